@@ -0,0 +1,103 @@
+package constraint
+
+import "github.com/mitchellh/go-sat/cnf"
+
+// This file implements the cardinality constraints (AtMost/AtLeast)
+// using Sinz's sequential counter encoding. Unlike a naive "every
+// (k+1)-subset is forbidden" encoding, which is exponential in the
+// number of subjects, the sequential counter encoding introduces O(n*k)
+// auxiliary variables and clauses, keeping the compiled formula linear
+// in the number of subjects even for large groups.
+
+// AtMost requires that no more than k of subjects are selected.
+func (p *Problem) AtMost(k int, subjects ...Identifier) {
+	owner := p.addConstraint("at-most", subjects...)
+	p.encodeAtMost(owner, k, subjects)
+}
+
+// AtLeast requires that at least k of subjects are selected. It is
+// encoded as an AtMost constraint over the negated subjects: at least k
+// of n subjects selected is equivalent to at most (n-k) of them
+// unselected.
+func (p *Problem) AtLeast(k int, subjects ...Identifier) {
+	owner := p.addConstraint("at-least", subjects...)
+
+	lits := make([]cnf.Literal, len(subjects))
+	for i, subj := range subjects {
+		lits[i] = cnf.Literal(-p.varFor(subj))
+	}
+
+	p.encodeAtMostLits(owner, len(subjects)-k, lits)
+}
+
+// encodeAtMost compiles "at most k of subjects" using the sequential
+// counter encoding and records the resulting clauses under owner.
+func (p *Problem) encodeAtMost(owner int, k int, subjects []Identifier) {
+	lits := make([]cnf.Literal, len(subjects))
+	for i, subj := range subjects {
+		lits[i] = cnf.Literal(p.varFor(subj))
+	}
+
+	p.encodeAtMostLits(owner, k, lits)
+}
+
+func (p *Problem) encodeAtMostLits(owner int, k int, x []cnf.Literal) {
+	n := len(x)
+
+	// k >= n is trivially satisfied.
+	if k >= n {
+		return
+	}
+
+	// k < 0 is an impossible bound (e.g. AtLeast(k) with k greater than
+	// the number of subjects): no selection can satisfy it, so add the
+	// empty clause to force the whole problem unsat.
+	if k < 0 {
+		p.addClause(owner, cnf.Clause{})
+		return
+	}
+
+	// k == 0 forbids every literal.
+	if k == 0 {
+		for _, lit := range x {
+			p.addClause(owner, cnf.Clause{-lit})
+		}
+		return
+	}
+
+	// s[i][j] (1-indexed i in 1..n-1, j in 1..k) means "at least j of
+	// x[1..i] are true". These are fresh auxiliary variables, not tied
+	// to any subject.
+	s := make([][]cnf.Literal, n)
+	for i := 1; i <= n-1; i++ {
+		s[i] = make([]cnf.Literal, k+1)
+		for j := 1; j <= k; j++ {
+			p.next++
+			s[i][j] = cnf.Literal(p.next)
+		}
+	}
+
+	xAt := func(i int) cnf.Literal { return x[i-1] }
+
+	// Base case: i = 1.
+	p.addClause(owner, cnf.Clause{-xAt(1), s[1][1]})
+	for j := 2; j <= k; j++ {
+		p.addClause(owner, cnf.Clause{-s[1][j]})
+	}
+
+	// Inductive case: i = 2..n-1.
+	for i := 2; i <= n-1; i++ {
+		p.addClause(owner, cnf.Clause{-xAt(i), s[i][1]})
+		p.addClause(owner, cnf.Clause{-s[i-1][1], s[i][1]})
+
+		for j := 2; j <= k; j++ {
+			p.addClause(owner, cnf.Clause{-xAt(i), -s[i-1][j-1], s[i][j]})
+			p.addClause(owner, cnf.Clause{-s[i-1][j], s[i][j]})
+		}
+
+		p.addClause(owner, cnf.Clause{-xAt(i), -s[i-1][k]})
+	}
+
+	// Final literal can't push the count past k either.
+	p.addClause(owner, cnf.Clause{-xAt(n), -s[n-1][k]})
+}
@@ -0,0 +1,163 @@
+// Package constraint provides a higher-level, dependency-resolution
+// style API on top of cnf/sat: callers describe a problem in terms of
+// opaque subjects and constraints between them (Mandatory, Prohibited,
+// Dependency, Conflict, and the cardinality constraints in
+// cardinality.go), and Problem compiles that down to CNF, hands it to a
+// sat.Solver, and reports back which subjects were selected.
+package constraint
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/go-sat"
+	"github.com/mitchellh/go-sat/cnf"
+)
+
+// Identifier is an opaque value identifying a subject in a Problem, e.g.
+// a package name or resource ID. It must be usable as a map key.
+type Identifier interface{}
+
+// Constraint describes one constraint added to a Problem, recorded so
+// that an unsat result can report which constraints were involved.
+type Constraint struct {
+	Kind     string
+	Subjects []Identifier
+}
+
+// Problem collects subjects and constraints between them and compiles
+// them to a CNF formula on Solve.
+type Problem struct {
+	vars map[Identifier]int
+	next int
+
+	clauses     cnf.Formula
+	clauseOwner map[string]int // clause (by fmt.Sprint) -> index into constraints
+	constraints []Constraint
+}
+
+// New creates an empty Problem.
+func New() *Problem {
+	return &Problem{
+		vars:        make(map[Identifier]int),
+		clauseOwner: make(map[string]int),
+	}
+}
+
+// varFor returns the CNF variable assigned to subject, allocating a new
+// one the first time subject is seen.
+func (p *Problem) varFor(subject Identifier) int {
+	if v, ok := p.vars[subject]; ok {
+		return v
+	}
+
+	p.next++
+	p.vars[subject] = p.next
+	return p.next
+}
+
+// addConstraint records a new constraint and returns its index, to be
+// passed to addClause for every clause the constraint compiles to.
+func (p *Problem) addConstraint(kind string, subjects ...Identifier) int {
+	p.constraints = append(p.constraints, Constraint{Kind: kind, Subjects: subjects})
+	return len(p.constraints) - 1
+}
+
+// addClause appends c to the compiled formula, owned by the constraint
+// at index owner.
+func (p *Problem) addClause(owner int, c cnf.Clause) {
+	p.clauses = append(p.clauses, c)
+	p.clauseOwner[fmt.Sprint(c)] = owner
+}
+
+// Mandatory requires subject to be selected.
+func (p *Problem) Mandatory(subject Identifier) {
+	owner := p.addConstraint("mandatory", subject)
+	p.addClause(owner, cnf.Clause{cnf.Literal(p.varFor(subject))})
+}
+
+// Prohibited requires subject to never be selected.
+func (p *Problem) Prohibited(subject Identifier) {
+	owner := p.addConstraint("prohibited", subject)
+	p.addClause(owner, cnf.Clause{cnf.Literal(-p.varFor(subject))})
+}
+
+// Dependency requires that if subject is selected, at least one of
+// options must be selected too: subject -> (option_1 OR ... OR option_n).
+func (p *Problem) Dependency(subject Identifier, options ...Identifier) {
+	owner := p.addConstraint("dependency", append([]Identifier{subject}, options...)...)
+
+	clause := make(cnf.Clause, 0, len(options)+1)
+	clause = append(clause, cnf.Literal(-p.varFor(subject)))
+	for _, opt := range options {
+		clause = append(clause, cnf.Literal(p.varFor(opt)))
+	}
+
+	p.addClause(owner, clause)
+}
+
+// Conflict requires that a and b are never both selected.
+func (p *Problem) Conflict(a, b Identifier) {
+	owner := p.addConstraint("conflict", a, b)
+	p.addClause(owner, cnf.Clause{
+		cnf.Literal(-p.varFor(a)),
+		cnf.Literal(-p.varFor(b)),
+	})
+}
+
+// Unsatisfiable is returned by Solve when no selection can satisfy
+// every constraint. Constraints holds the subset of added constraints
+// that participated in the conflict, derived from the compiled
+// formula's unsat core.
+type Unsatisfiable struct {
+	Constraints []Constraint
+}
+
+func (u *Unsatisfiable) Error() string {
+	return fmt.Sprintf("constraint: unsatisfiable (%d conflicting constraints)", len(u.Constraints))
+}
+
+// Solve compiles every constraint added so far to CNF, solves it, and
+// returns the selected identifiers. If no selection satisfies every
+// constraint, it returns an *Unsatisfiable describing which constraints
+// conflicted.
+func (p *Problem) Solve() ([]Identifier, error) {
+	s := sat.New()
+	s.AddFormula(p.clauses)
+
+	if !s.Solve() {
+		return nil, &Unsatisfiable{Constraints: p.coreConstraints(s.UnsatCore())}
+	}
+
+	res := s.Result()
+
+	selected := make([]Identifier, 0, len(p.vars))
+	for subject, v := range p.vars {
+		if res.Model[v] {
+			selected = append(selected, subject)
+		}
+	}
+
+	return selected, nil
+}
+
+// coreConstraints maps a set of CNF clauses (as returned by
+// sat.Solver.UnsatCore) back to the constraints that produced them.
+func (p *Problem) coreConstraints(core []cnf.Clause) []Constraint {
+	seen := map[int]struct{}{}
+	var result []Constraint
+
+	for _, c := range core {
+		owner, ok := p.clauseOwner[fmt.Sprint(c)]
+		if !ok {
+			continue
+		}
+		if _, ok := seen[owner]; ok {
+			continue
+		}
+
+		seen[owner] = struct{}{}
+		result = append(result, p.constraints[owner])
+	}
+
+	return result
+}
@@ -0,0 +1,128 @@
+package constraint
+
+import "testing"
+
+func contains(ids []Identifier, want Identifier) bool {
+	for _, id := range ids {
+		if id == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestProblem_mandatoryAndConflict(t *testing.T) {
+	p := New()
+	p.Mandatory("a")
+	p.Conflict("a", "b")
+
+	selected, err := p.Solve()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !contains(selected, "a") {
+		t.Fatalf("expected 'a' selected, got %#v", selected)
+	}
+	if contains(selected, "b") {
+		t.Fatalf("expected 'b' not selected, got %#v", selected)
+	}
+}
+
+func TestProblem_prohibitedConflictsWithMandatory(t *testing.T) {
+	p := New()
+	p.Mandatory("a")
+	p.Prohibited("a")
+
+	if _, err := p.Solve(); err == nil {
+		t.Fatal("expected unsat")
+	}
+}
+
+func TestProblem_dependency(t *testing.T) {
+	p := New()
+	p.Mandatory("a")
+	p.Dependency("a", "b", "c")
+	p.Prohibited("b")
+
+	selected, err := p.Solve()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !contains(selected, "c") {
+		t.Fatalf("expected 'c' selected to satisfy the dependency, got %#v", selected)
+	}
+}
+
+func TestProblem_atMost(t *testing.T) {
+	p := New()
+	p.Mandatory("a")
+	p.Mandatory("b")
+	p.AtMost(1, "a", "b")
+
+	if _, err := p.Solve(); err == nil {
+		t.Fatal("expected unsat: both 'a' and 'b' mandatory violates AtMost(1)")
+	}
+}
+
+// AtLeast(k, subjects...) with k greater than len(subjects) must be
+// unsatisfiable, not silently force every subject mandatory.
+func TestProblem_atLeastImpossibleBound(t *testing.T) {
+	p := New()
+	p.AtLeast(5, "a", "b")
+
+	if _, err := p.Solve(); err == nil {
+		t.Fatal("expected unsat: can't select 5 of only 2 subjects")
+	}
+}
+
+func TestProblem_atLeastSatisfiable(t *testing.T) {
+	p := New()
+	p.AtLeast(2, "a", "b", "c")
+
+	selected, err := p.Solve()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(selected) < 2 {
+		t.Fatalf("expected at least 2 selected, got %#v", selected)
+	}
+}
+
+// A problem combining several constraint kinds at once: a regression
+// guard for the full Solve -> sat.Solver.Result -> selected round trip,
+// not just each constraint kind in isolation.
+func TestProblem_combined(t *testing.T) {
+	p := New()
+	p.Mandatory("a")
+	p.Dependency("a", "b", "c")
+	p.Prohibited("b")
+	p.AtLeast(2, "c", "d", "e")
+
+	selected, err := p.Solve()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !contains(selected, "a") {
+		t.Fatalf("expected 'a' selected, got %#v", selected)
+	}
+	if contains(selected, "b") {
+		t.Fatalf("expected 'b' not selected, got %#v", selected)
+	}
+	if !contains(selected, "c") {
+		t.Fatalf("expected 'c' selected to satisfy the dependency, got %#v", selected)
+	}
+
+	cardinality := 0
+	for _, id := range []Identifier{"c", "d", "e"} {
+		if contains(selected, id) {
+			cardinality++
+		}
+	}
+	if cardinality < 2 {
+		t.Fatalf("expected at least 2 of c/d/e selected, got %#v", selected)
+	}
+}
@@ -0,0 +1,84 @@
+package dimacs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Result is the parsed form of a DIMACS solver-output file: the
+// "s SATISFIABLE" / "s UNSATISFIABLE" status line, plus (when
+// satisfiable) the variable assignments read off of the "v ..." line(s).
+// This is the inverse of what Solver.WriteModelDIMACS produces, and is
+// the format emitted by most standalone DIMACS solvers (minisat,
+// kissat, cadical, ...).
+type Result struct {
+	Sat   bool
+	Model map[int]bool
+}
+
+// ParseResult reads a DIMACS-format solver result from r.
+func ParseResult(r io.Reader) (Result, error) {
+	var result Result
+	var sawStatus bool
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		switch line[0] {
+		case 's':
+			sawStatus = true
+			switch strings.TrimSpace(line[1:]) {
+			case "SATISFIABLE":
+				result.Sat = true
+				result.Model = make(map[int]bool)
+			case "UNSATISFIABLE":
+				result.Sat = false
+			default:
+				return Result{}, fmt.Errorf("dimacs: unknown status line: %q", line)
+			}
+
+		case 'v':
+			if !result.Sat {
+				return Result{}, fmt.Errorf("dimacs: model line with no SATISFIABLE status: %q", line)
+			}
+
+			for _, f := range strings.Fields(line[1:]) {
+				n, err := strconv.Atoi(f)
+				if err != nil {
+					return Result{}, fmt.Errorf("dimacs: invalid literal %q: %w", f, err)
+				}
+				if n == 0 {
+					continue
+				}
+
+				v := n
+				if v < 0 {
+					v = -v
+				}
+				result.Model[v] = n > 0
+			}
+
+		case 'c':
+			// Comment line, ignore.
+
+		default:
+			return Result{}, fmt.Errorf("dimacs: unexpected result line: %q", line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Result{}, err
+	}
+	if !sawStatus {
+		return Result{}, fmt.Errorf("dimacs: no status line found")
+	}
+
+	return result, nil
+}
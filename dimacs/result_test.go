@@ -0,0 +1,37 @@
+package dimacs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseResult_sat(t *testing.T) {
+	in := "c a comment\ns SATISFIABLE\nv 1 -2 3 0\n"
+
+	r, err := ParseResult(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !r.Sat {
+		t.Fatal("expected Sat = true")
+	}
+
+	expected := map[int]bool{1: true, 2: false, 3: true}
+	for v, want := range expected {
+		if got := r.Model[v]; got != want {
+			t.Fatalf("var %d: got %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestParseResult_unsat(t *testing.T) {
+	r, err := ParseResult(strings.NewReader("s UNSATISFIABLE\n"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if r.Sat {
+		t.Fatal("expected Sat = false")
+	}
+}
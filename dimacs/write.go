@@ -0,0 +1,32 @@
+package dimacs
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mitchellh/go-sat/cnf"
+)
+
+// WriteFormula writes f in DIMACS CNF input format: a "p cnf <vars>
+// <clauses>" header line followed by one "0"-terminated line per
+// clause. maxVar is the highest variable number appearing in f; callers
+// that already track it (as Solver does) can pass it directly instead
+// of making WriteFormula scan the whole formula again.
+func WriteFormula(w io.Writer, f cnf.Formula, maxVar int) error {
+	if _, err := fmt.Fprintf(w, "p cnf %d %d\n", maxVar, len(f)); err != nil {
+		return err
+	}
+
+	for _, c := range f {
+		for _, l := range c {
+			if _, err := fmt.Fprintf(w, "%d ", int(l)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, "0"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package sat
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/mitchellh/go-sat/cnf"
+	"github.com/mitchellh/go-sat/dimacs"
+)
+
+// ExternalSolver solves formulas by shelling out to a standalone DIMACS
+// SAT solver binary (minisat, kissat, cadical, ...) instead of using the
+// in-process Solver. It implements SATSolver, so callers that want a
+// production-grade solver can swap one in without touching any other
+// call sites.
+type ExternalSolver struct {
+	// Path is the solver binary to exec, e.g. "minisat" or
+	// "/usr/local/bin/kissat".
+	Path string
+
+	// Args are extra arguments passed to Path before the input and
+	// output file paths, e.g. []string{"--verbosity=0"}.
+	Args []string
+
+	f      cnf.Formula
+	maxVar int
+	result Result
+}
+
+// AddFormula sets the formula to solve.
+func (s *ExternalSolver) AddFormula(f cnf.Formula) {
+	s.f = f
+
+	s.maxVar = 0
+	for _, c := range f {
+		for _, l := range c {
+			v := int(l)
+			if v < 0 {
+				v = -v
+			}
+			if v > s.maxVar {
+				s.maxVar = v
+			}
+		}
+	}
+}
+
+// Solve writes the formula to a temporary DIMACS input file, execs Path
+// against it, and parses the resulting DIMACS solver-output file. It
+// returns true if the external solver reported the formula satisfiable.
+//
+// Solve panics if the input/output files can't be created or the
+// solver's output can't be parsed; a misbehaving external solver isn't a
+// recoverable condition callers are expected to handle inline, matching
+// how Solver treats a malformed internal state.
+func (s *ExternalSolver) Solve() bool {
+	in, err := os.CreateTemp("", "go-sat-*.cnf")
+	if err != nil {
+		panic(fmt.Sprintf("sat: creating external solver input file: %s", err))
+	}
+	defer os.Remove(in.Name())
+	defer in.Close()
+
+	if err := dimacs.WriteFormula(in, s.f, s.maxVar); err != nil {
+		panic(fmt.Sprintf("sat: writing external solver input: %s", err))
+	}
+	in.Close()
+
+	out, err := os.CreateTemp("", "go-sat-*.out")
+	if err != nil {
+		panic(fmt.Sprintf("sat: creating external solver output file: %s", err))
+	}
+	defer os.Remove(out.Name())
+	out.Close()
+
+	args := append(append([]string{}, s.Args...), in.Name(), out.Name())
+	cmd := exec.Command(s.Path, args...)
+
+	// Most DIMACS solvers exit non-zero on UNSAT (and on some solvers,
+	// even on SAT), so the exit code can't be used to detect failure;
+	// the result file contents are the source of truth.
+	_ = cmd.Run()
+
+	outF, err := os.Open(out.Name())
+	if err != nil {
+		panic(fmt.Sprintf("sat: reading external solver output: %s", err))
+	}
+	defer outF.Close()
+
+	res, err := dimacs.ParseResult(outF)
+	if err != nil {
+		panic(fmt.Sprintf("sat: parsing external solver output: %s", err))
+	}
+
+	s.result = Result{Sat: res.Sat, Model: res.Model}
+	return s.result.Sat
+}
+
+// Result returns the outcome of the most recent Solve call.
+func (s *ExternalSolver) Result() Result {
+	return s.result
+}
+
+var _ SATSolver = (*ExternalSolver)(nil)
@@ -2,6 +2,7 @@ package sat
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/mitchellh/go-sat/cnf"
 	"github.com/mitchellh/go-sat/packed"
@@ -31,11 +32,50 @@ type Solver struct {
 	// behavior being tested.
 	decideLiterals []int
 
+	// VarDecay is the factor by which every variable's VSIDS activity is
+	// decayed after each conflict. Values closer to 1 remember conflict
+	// history longer; values closer to 0 favor recently-involved
+	// variables more aggressively. Defaults to 0.95.
+	VarDecay float64
+
+	// RestartUnit is the base number of conflicts (the Luby sequence
+	// "unit") used to size the interval between restarts. Defaults to
+	// 100.
+	RestartUnit int
+
+	// RestartEnabled controls whether the solver performs Luby-sequence
+	// restarts at all. Defaults to true.
+	RestartEnabled bool
+
 	//---------------------------------------------------------------
 	// Internal fields, do not set
 	//---------------------------------------------------------------
 	result satResult
 
+	// VSIDS branching heuristic state.
+	activity              map[int]float64
+	varInc                float64
+	varHeap               *varHeap
+	conflictsSinceRestart int
+	restartCount          int
+
+	// Incremental-solving (assumption) state.
+	assumptions  []cnf.Literal // every assumed literal, in Assume order
+	assumeFrames []int         // start index in assumptions of each Assume call
+	assumeFailed bool          // true if Solve returned unsat under assumptions, unresolved by Untest
+	failedCore   []cnf.Literal // assumption literals implicated in that conflict
+
+	// Unsat-core / resolution-proof bookkeeping.
+	clauseIDs        map[string]int // clause (by fmt.Sprint) -> ID
+	clauseByID       []cnf.Clause   // ID -> clause
+	proofAntecedents []int          // antecedent clause IDs for the step being built
+	proofTrace       []proofStep    // completed resolution steps, in derivation order
+
+	// Two-watched-literal BCP state. See solver_watch.go.
+	watches      map[cnf.Literal][]watcher
+	watchedCount int // number of clauses in s.f already indexed into watches
+	propQhead    int // index into s.m.elems of the next literal to propagate
+
 	f         cnf.Formula // formula we're solving
 	m         *trail
 	reasonMap map[cnf.Literal]cnf.Clause
@@ -59,9 +99,15 @@ type Solver struct {
 
 // New creates a new solver and allocates the basics for it.
 func New() *Solver {
+	activity := make(map[int]float64)
+
 	return &Solver{
 		result: satResultUndef,
 
+		VarDecay:       0.95,
+		RestartUnit:    100,
+		RestartEnabled: true,
+
 		m:         newTrail(),
 		reasonMap: make(map[cnf.Literal]cnf.Clause),
 
@@ -70,6 +116,17 @@ func New() *Solver {
 
 		// trail
 		assigns: make(map[int]Tribool),
+
+		// VSIDS
+		activity: activity,
+		varInc:   1.0,
+		varHeap:  newVarHeap(activity),
+
+		// unsat core / proof
+		clauseIDs: make(map[string]int),
+
+		// two-watched-literal BCP
+		watches: make(map[cnf.Literal][]watcher),
 	}
 }
 
@@ -90,6 +147,30 @@ func (s *Solver) Solve() bool {
 		return s.result == satResultSat
 	}
 
+	s.cleanupSolve()
+
+	// Assumptions accumulate across calls, and a prior Solve call may
+	// have left the trail populated past level 0 (e.g. a satisfying
+	// assignment, or decisions made while checking an earlier, smaller
+	// assumption set). Trim back to level 0 and replay every
+	// accumulated assumption fresh, rather than risk re-asserting a
+	// literal that's already on the trail from last time.
+	s.m.TrimToLevel(0)
+	s.reinsertUnassigned()
+
+	// Assert every assumption literal as its own decision before the
+	// normal search begins. If propagating one of them already falsifies
+	// the formula, the loop below will detect it as a conflict at or
+	// below the assumption decision levels.
+	for _, lit := range s.assumptions {
+		if !s.m.IsFormulaFalse(s.f).IsZero() {
+			break
+		}
+
+		s.assertLiteral(lit, true)
+		s.unitPropagate()
+	}
+
 	for {
 		// Perform unit propagation
 		s.unitPropagate()
@@ -105,20 +186,50 @@ func (s *Solver) Solve() bool {
 			s.applyConflict(conflictC)
 
 			// If we have no more decisions within the trail, then we've
-			// failed finding a satisfying value.
+			// failed finding a satisfying value. The accumulated
+			// antecedents resolve down to the empty clause.
 			if s.m.DecisionsLen() == 0 {
+				s.recordLearnedClause(cnf.Clause{})
+				s.result = satResultUnsat
 				return false
 			}
 
 			// Explain to learn our conflict clause
 			s.applyExplainUIP()
+
+			// A conflict found while every decision on the trail is an
+			// assumption can't be resolved by backtracking: it's unsat
+			// under the current assumptions. Record the implicated
+			// assumptions as the failed-assumption core instead of
+			// continuing the search. s.result stays undefined, so the
+			// next Solve call reaches cleanupSolve until Untest resolves
+			// this result.
+			if len(s.assumptions) > 0 && s.m.CurrentLevel() <= len(s.assumptions) {
+				s.assumeFailed = true
+				s.failedCore = s.computeFailedCore()
+				return false
+			}
+
 			if len(s.c) > 1 {
 				if s.Trace {
 					s.Tracer.Printf("[TRACE] sat: learned clause: %#v", s.c)
 				}
 				s.f = append(s.f, s.c)
 			}
+			s.decayActivity()
 			s.applyBackjump()
+
+			s.conflictsSinceRestart++
+			if s.shouldRestart() {
+				s.restart()
+			}
+
+			// A backjump or restart below the assumption levels undoes
+			// assumption decisions; assert them again, one level each,
+			// before the search goes on.
+			for i := s.m.CurrentLevel(); i < len(s.assumptions); i++ {
+				s.assertLiteral(s.assumptions[i], true)
+			}
 		} else {
 			// If the trail contains the same number of elements as
 			// the variables in the formula, then we've found a satisfaction.
@@ -127,6 +238,7 @@ func (s *Solver) Solve() bool {
 					s.Tracer.Printf("[TRACE] sat: solver found solution: %s", s.m)
 				}
 
+				s.result = satResultSat
 				return true
 			}
 
@@ -165,55 +277,41 @@ func (s *Solver) selectLiteral() cnf.Literal {
 		return result
 	}
 
-	for raw, _ := range s.vars {
-		k := cnf.Literal(raw)
-		if _, ok := tMap[k]; !ok {
+	// Lazily make sure every known variable is represented in the VSIDS
+	// heap, then pop the highest-activity variable that isn't already
+	// assigned.
+	s.syncVarHeap()
+	for {
+		v, ok := s.varHeap.pop()
+		if !ok {
+			return cnf.Literal(0)
+		}
+
+		k := cnf.Literal(v)
+		if _, assigned := tMap[k]; !assigned {
 			return k
 		}
 	}
-
-	return cnf.Literal(0)
 }
 
 //-------------------------------------------------------------------
 // Unit Propagation
 //-------------------------------------------------------------------
-
-func (s *Solver) unitPropagate() {
-	for {
-		for _, c := range s.f {
-			for _, l := range c {
-				if s.m.IsUnit(c, l) {
-					if s.Trace {
-						s.Tracer.Printf(
-							"[TRACE] sat: found unit clause %v with literal %d in trail %s",
-							c, l, s.m)
-					}
-
-					s.assertLiteral(l, false)
-					s.reasonMap[l] = c
-					goto UNIT_REPEAT
-				}
-			}
-		}
-
-		// We didn't find a unit clause, close it out
-		return
-
-	UNIT_REPEAT:
-		// We found a unit clause but we have to check if we violated
-		// constraints in the trail.
-		if !s.m.IsFormulaFalse(s.f).IsZero() {
-			return
-		}
-	}
-}
+//
+// unitPropagate and its helpers live in solver_watch.go: they implement
+// a two-watched-literal scheme so that BCP cost is proportional to the
+// number of assignments made, rather than rescanning every clause on
+// every asserted literal.
 
 //-------------------------------------------------------------------
 // Conflict Clause Learning
 //-------------------------------------------------------------------
 
 func (s *Solver) applyConflict(c cnf.Clause) {
+	// Start a fresh resolution step: c is the first antecedent.
+	s.proofAntecedents = nil
+	s.recordAntecedent(c)
+
 	// Build up our lookup caches for the conflict data to optimize
 	// the conflict learning process.
 	s.cH = make(map[cnf.Literal]struct{})
@@ -266,6 +364,7 @@ func (s *Solver) applyExplain(lit cnf.Literal) {
 	s.removeConflictLiteral(lit.Negate())
 
 	reason := s.reasonMap[lit]
+	s.recordAntecedent(reason)
 	for _, l := range reason {
 		if l != lit {
 			s.addConflictLiteral(l)
@@ -294,12 +393,24 @@ func (s *Solver) applyExplainUIP() {
 	}
 
 	// buildC
+	//
+	// s.cP is a map, so ranging over it directly would order the
+	// non-asserting literals randomly from one run to the next. Two runs
+	// that learn the same clause content would then register it under
+	// different registerClause keys (keyed on fmt.Sprint(c)), defeating
+	// that function's "reuse an ID for an identical clause" dedup and
+	// making proof traces non-deterministic for no reason. Sort first so
+	// clause identity only depends on content.
 	c := make([]cnf.Literal, 0, len(s.cP)+1)
-	for l, _ := range s.cP {
+	for l := range s.cP {
 		c = append(c, l)
 	}
+	sort.Slice(c, func(i, j int) bool { return c[i] < c[j] })
 	c = append(c, s.cL.Negate())
 	s.c = c
+
+	s.recordLearnedClause(s.c)
+	s.bumpLearnedClause()
 }
 
 func (s *Solver) isUIP() bool {
@@ -327,6 +438,7 @@ func (s *Solver) applyBackjump() {
 	}
 
 	s.m.TrimToLevel(level)
+	s.reinsertUnassigned()
 
 	lit := s.cL.Negate()
 	s.assertLiteral(lit, false)
@@ -0,0 +1,166 @@
+package sat
+
+import (
+	"github.com/mitchellh/go-sat/cnf"
+	"github.com/mitchellh/go-sat/packed"
+)
+
+// This file implements an incremental-SAT API on top of Solver, modeled
+// on the classic Test/Assume/Untest pattern (as used by e.g. MiniSat's
+// assumption interface and gini): callers push a set of assumption
+// literals with Assume, call Solve to check satisfiability under those
+// assumptions, and either keep the result or pop the assumptions with
+// Untest and try something else. Clauses learned while solving are never
+// discarded by Untest, so later Solve calls benefit from everything
+// learned so far.
+
+// Assume pushes a new frame of assumption literals onto the solver. All
+// literals passed in a single Assume call are popped together by the
+// next matching Untest call. Assumptions accumulate across calls: the
+// next Solve is checked against every literal assumed so far, not just
+// the latest frame.
+//
+// Assume panics if called while the solver is in an unresolved unsat
+// state (the previous Solve returned false under the current
+// assumptions); call Untest first to back out of that result.
+func (s *Solver) Assume(lits ...packed.Lit) {
+	s.cleanupSolve()
+
+	s.assumeFrames = append(s.assumeFrames, len(s.assumptions))
+	for _, l := range lits {
+		s.assumptions = append(s.assumptions, litFromPacked(l))
+	}
+
+	// A new assumption invalidates any cached result from a prior Solve.
+	s.result = satResultUndef
+}
+
+// Untest pops the most recently pushed Assume frame, undoing its
+// literals. It also clears any unresolved-unsat state left by a prior
+// Solve, so Assume and Solve may be called again.
+func (s *Solver) Untest() {
+	if len(s.assumeFrames) == 0 {
+		return
+	}
+
+	last := s.assumeFrames[len(s.assumeFrames)-1]
+	s.assumeFrames = s.assumeFrames[:len(s.assumeFrames)-1]
+	s.assumptions = s.assumptions[:last]
+
+	s.assumeFailed = false
+	s.failedCore = nil
+	s.result = satResultUndef
+
+	s.m.TrimToLevel(0)
+	s.reinsertUnassigned()
+}
+
+// Failed returns the subset of currently-assumed literals that
+// participated in the conflict which made the last Solve call return
+// false. This is the "failed assumptions" core: asserting every returned
+// literal is sufficient (along with the original clauses) to derive the
+// conflict. It is only meaningful immediately
+// after a Solve call returns false with assumptions in effect.
+func (s *Solver) Failed() []packed.Lit {
+	out := make([]packed.Lit, 0, len(s.failedCore))
+	for _, lit := range s.failedCore {
+		out = append(out, litToPacked(lit))
+	}
+
+	return out
+}
+
+// cleanupSolve enforces the incremental-solving invariant: once Solve
+// has returned unsat under the current assumptions, that result must be
+// resolved with Untest before the solver will accept new assumptions or
+// another Solve call. Continuing to search (or to assume) past an
+// unresolved unsat would mix conflict state from two different
+// assumption sets, which mirrors the invariant gini enforces in its own
+// Test/Assume/Untest implementation.
+func (s *Solver) cleanupSolve() {
+	if s.assumeFailed {
+		panic("sat: Solver has an unresolved unsat result; call Untest before Assume or Solve")
+	}
+}
+
+// assumptionConflictLevel returns the decision level that a backjump
+// from the current conflict would land on, without performing the
+// backjump.
+func (s *Solver) assumptionConflictLevel() int {
+	level := 0
+	for l := range s.cP {
+		if v := s.m.set[l.Negate()]; v > level {
+			level = v
+		}
+	}
+
+	return level
+}
+
+// computeFailedCore walks the reason DAG of the current conflict
+// backwards from the conflict clause's literals to the assumption
+// literals that ultimately forced them, collecting the assumptions
+// implicated along the way.
+func (s *Solver) computeFailedCore() []cnf.Literal {
+	assumeSet := make(map[cnf.Literal]struct{}, len(s.assumptions))
+	for _, a := range s.assumptions {
+		assumeSet[a] = struct{}{}
+	}
+
+	seen := map[cnf.Literal]struct{}{}
+	core := []cnf.Literal{}
+
+	var walk func(lit cnf.Literal)
+	walk = func(lit cnf.Literal) {
+		if _, ok := seen[lit]; ok {
+			return
+		}
+		seen[lit] = struct{}{}
+
+		if _, ok := assumeSet[lit.Negate()]; ok {
+			core = append(core, lit.Negate())
+			return
+		}
+
+		reason, ok := s.reasonMap[lit.Negate()]
+		if !ok {
+			return
+		}
+		// The other literals in the reason clause are false under the
+		// current assignment by construction (that's what made the
+		// clause unit), i.e. they're already in the same false-form
+		// convention as lit itself — same as applyExplain's use of
+		// addConflictLiteral(l) without negating l.
+		for _, l := range reason {
+			if l != lit.Negate() {
+				walk(l)
+			}
+		}
+	}
+
+	for l := range s.cH {
+		walk(l)
+	}
+
+	return core
+}
+
+func litFromPacked(l packed.Lit) cnf.Literal {
+	v := cnf.Literal(l.Var())
+	if l.Sign() {
+		v = -v
+	}
+
+	return v
+}
+
+func litToPacked(l cnf.Literal) packed.Lit {
+	v := int(l)
+	sign := false
+	if v < 0 {
+		v = -v
+		sign = true
+	}
+
+	return packed.NewLit(v, sign)
+}
@@ -0,0 +1,96 @@
+package sat
+
+import (
+	"testing"
+
+	"github.com/mitchellh/go-sat/cnf"
+	"github.com/mitchellh/go-sat/packed"
+)
+
+func TestSolver_assume(t *testing.T) {
+	// -3 4
+	// -1 -3 5
+	// -2 -4 -5
+	// -2 3 5 -6
+	// -1 2
+	// -1 3 -5 -6
+	// 1 -6
+	// 1 7
+	formula := [][]int{
+		{-3, 4},
+		{-1, -3, 5},
+		{-2, -4, -5},
+		{-2, 3, 5, -6},
+		{-1, 2},
+		{-1, 3, -5, -6},
+		{1, -6},
+		{1, 7},
+	}
+
+	s := New()
+	s.Trace = true
+	s.Tracer = newTracer(t)
+	s.AddFormula(cnf.NewFormulaFromInts(formula))
+
+	s.Assume(packed.NewLit(1, false))
+	if !s.Solve() {
+		t.Fatal("expected sat under assumption 1")
+	}
+
+	s.Untest()
+
+	s.Assume(packed.NewLit(1, true))
+	if !s.Solve() {
+		t.Fatal("expected sat under assumption -1")
+	}
+}
+
+func TestSolver_assumeFailed(t *testing.T) {
+	formula := [][]int{
+		{4},
+		{6},
+	}
+
+	s := New()
+	s.Trace = true
+	s.Tracer = newTracer(t)
+	s.AddFormula(cnf.NewFormulaFromInts(formula))
+
+	// Assuming -4 directly contradicts the unit clause {4}.
+	s.Assume(packed.NewLit(4, true))
+	if s.Solve() {
+		t.Fatal("expected unsat under assumption -4")
+	}
+
+	failed := s.Failed()
+	if len(failed) == 0 {
+		t.Fatal("expected at least one failed assumption")
+	}
+
+	found := false
+	for _, l := range failed {
+		if l.Var() == 4 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected failed core to include var 4, got %#v", failed)
+	}
+
+	// Solve/Assume should refuse to continue until Untest resolves the
+	// unsat result.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic from Solve after unresolved unsat")
+			}
+		}()
+		s.Solve()
+	}()
+
+	s.Untest()
+
+	if !s.Solve() {
+		t.Fatal("expected sat once the failing assumption is retracted")
+	}
+}
@@ -0,0 +1,94 @@
+package sat
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/go-sat/cnf"
+)
+
+// Result describes the outcome of solving a formula: whether it was
+// satisfiable, and if so, the boolean assignment found for every
+// variable.
+type Result struct {
+	Sat   bool
+	Model map[int]bool
+}
+
+// SATSolver is the minimal interface satisfied by both the in-process
+// Solver and ExternalSolver. Code written against SATSolver can swap
+// between the two (e.g. to delegate to a production solver binary)
+// without any other changes.
+type SATSolver interface {
+	AddFormula(f cnf.Formula)
+	Solve() bool
+	Result() Result
+}
+
+// Result returns the outcome of the most recent Solve call as a Result.
+// Before Solve has been called, or when the formula was unsatisfiable,
+// Result.Model is nil.
+func (s *Solver) Result() Result {
+	if s.result != satResultSat {
+		return Result{Sat: false}
+	}
+
+	// The satisfying assignment lives on the trail, not in the legacy
+	// s.assigns map (which nothing in the active solve path writes to).
+	// Every variable appears exactly once in s.m.elems by the time Solve
+	// returns true, signed to indicate its assigned value, the same
+	// convention selectLiteral and reinsertUnassigned read it with.
+	model := make(map[int]bool, len(s.m.elems))
+	for _, e := range s.m.elems {
+		lit := e.Lit
+		v := int(lit)
+		sign := v > 0
+		if v < 0 {
+			v = -v
+		}
+		model[v] = sign
+	}
+
+	return Result{Sat: true, Model: model}
+}
+
+// WriteModelDIMACS writes the result of the most recent Solve call in
+// the standard DIMACS solver-output format: a single "s SATISFIABLE" or
+// "s UNSATISFIABLE" status line, followed (when satisfiable) by one or
+// more "v ..." lines listing every variable as a signed literal and
+// terminated by a trailing 0. dimacs.ParseResult reads this format back.
+func (s *Solver) WriteModelDIMACS(w io.Writer) error {
+	res := s.Result()
+
+	if !res.Sat {
+		_, err := fmt.Fprintln(w, "s UNSATISFIABLE")
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "s SATISFIABLE"); err != nil {
+		return err
+	}
+
+	vars := make([]int, 0, len(res.Model))
+	for v := range res.Model {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+
+	lits := make([]string, 0, len(vars)+1)
+	for _, v := range vars {
+		if !res.Model[v] {
+			v = -v
+		}
+		lits = append(lits, strconv.Itoa(v))
+	}
+	lits = append(lits, "0")
+
+	_, err := fmt.Fprintln(w, "v "+strings.Join(lits, " "))
+	return err
+}
+
+var _ SATSolver = (*Solver)(nil)
@@ -0,0 +1,108 @@
+package sat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/go-sat/cnf"
+)
+
+func TestSolver_resultModel(t *testing.T) {
+	// -3 4
+	// -1 -3 5
+	// -2 -4 -5
+	// -2 3 5 -6
+	// -1 2
+	// -1 3 -5 -6
+	// 1 -6
+	// 1 7
+	formula := [][]int{
+		{-3, 4},
+		{-1, -3, 5},
+		{-2, -4, -5},
+		{-2, 3, 5, -6},
+		{-1, 2},
+		{-1, 3, -5, -6},
+		{1, -6},
+		{1, 7},
+	}
+
+	s := New()
+	s.Trace = true
+	s.Tracer = newTracer(t)
+	s.AddFormula(cnf.NewFormulaFromInts(formula))
+
+	if !s.Solve() {
+		t.Fatal("expected sat")
+	}
+
+	res := s.Result()
+	if !res.Sat {
+		t.Fatal("expected Result().Sat = true")
+	}
+
+	for _, clause := range formula {
+		satisfied := false
+		for _, lit := range clause {
+			v, want := lit, true
+			if v < 0 {
+				v, want = -v, false
+			}
+
+			if res.Model[v] == want {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			t.Fatalf("clause %v not satisfied by model %#v", clause, res.Model)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteModelDIMACS(&buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "s SATISFIABLE") {
+		t.Fatalf("expected a SATISFIABLE status line, got %q", out)
+	}
+	if !strings.Contains(out, "v ") {
+		t.Fatalf("expected a model line, got %q", out)
+	}
+}
+
+func TestSolver_resultUnsat(t *testing.T) {
+	formula := [][]int{
+		{4},
+		{6},
+		{-4, -6},
+	}
+
+	s := New()
+	s.Trace = true
+	s.Tracer = newTracer(t)
+	s.AddFormula(cnf.NewFormulaFromInts(formula))
+
+	if s.Solve() {
+		t.Fatal("expected unsat")
+	}
+
+	res := s.Result()
+	if res.Sat {
+		t.Fatalf("expected Result().Sat = false, got %#v", res)
+	}
+	if res.Model != nil {
+		t.Fatalf("expected a nil model, got %#v", res.Model)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteModelDIMACS(&buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if buf.String() != "s UNSATISFIABLE\n" {
+		t.Fatalf("expected an UNSATISFIABLE status line, got %q", buf.String())
+	}
+}
@@ -0,0 +1,170 @@
+package sat
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/go-sat/cnf"
+)
+
+// This file implements unsat-core extraction and DRAT-style resolution
+// proof logging. Every clause the solver ever reasons over (original or
+// learned) is tagged with a stable integer ID the first time it's used
+// in a conflict; applyExplain/applyExplainUIP then record, for each
+// learned clause, which antecedent clause IDs were resolved together to
+// derive it. When Solve finds the formula unsat, walking that DAG
+// backward from the final derivation and keeping only the non-learned
+// (original) clause IDs yields the unsat core.
+
+// proofStep records one resolution step: the clause IDs resolved
+// together (antecedents) to derive the Learned clause ID.
+type proofStep struct {
+	Learned     int
+	Antecedents []int
+}
+
+// ResolutionProof is a DRAT-style trace of every clause learned while
+// solving, in the order it was derived. It is only meaningful after a
+// Solve call has returned false.
+type ResolutionProof struct {
+	steps   []proofStep
+	clauses map[int]cnf.Clause
+}
+
+// WriteDRAT emits the proof's learned clauses in DRAT format: one
+// clause per line, space-separated literals terminated by a trailing 0.
+// External proof checkers (e.g. drat-trim) can verify the resulting
+// trace against the original formula.
+func (p ResolutionProof) WriteDRAT(w io.Writer) error {
+	for _, step := range p.steps {
+		c := p.clauses[step.Learned]
+
+		parts := make([]string, 0, len(c)+1)
+		for _, l := range c {
+			parts = append(parts, strconv.Itoa(int(l)))
+		}
+		parts = append(parts, "0")
+
+		if _, err := fmt.Fprintln(w, strings.Join(parts, " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// registerClause assigns c a stable ID, reusing one already assigned to
+// an identical clause. IDs are assigned lazily, the first time a clause
+// participates in conflict analysis, rather than up front for every
+// clause in the formula.
+func (s *Solver) registerClause(c cnf.Clause) int {
+	key := fmt.Sprint(c)
+	if id, ok := s.clauseIDs[key]; ok {
+		return id
+	}
+
+	id := len(s.clauseByID)
+	s.clauseIDs[key] = id
+	s.clauseByID = append(s.clauseByID, c)
+
+	return id
+}
+
+// recordAntecedent tags c with an ID (registering it if necessary) and
+// adds it to the antecedent list for the resolution step currently
+// being built.
+func (s *Solver) recordAntecedent(c cnf.Clause) {
+	id := s.registerClause(c)
+	s.proofAntecedents = append(s.proofAntecedents, id)
+}
+
+// recordLearnedClause closes out the resolution step for the clause
+// just derived by applyExplainUIP, then resets the antecedent
+// accumulator for the next conflict.
+func (s *Solver) recordLearnedClause(c cnf.Clause) {
+	learned := s.registerClause(c)
+
+	antecedents := make([]int, len(s.proofAntecedents))
+	copy(antecedents, s.proofAntecedents)
+
+	s.proofTrace = append(s.proofTrace, proofStep{
+		Learned:     learned,
+		Antecedents: antecedents,
+	})
+	s.proofAntecedents = nil
+}
+
+// UnsatCore returns a subset of the original input clauses whose
+// conjunction is still unsatisfiable, derived by walking the resolution
+// proof backward from the final conflict, together with the reasons of
+// literals fixed at decision level 0, and discarding every learned
+// clause along the way. It is only meaningful after Solve returns false.
+func (s *Solver) UnsatCore() []cnf.Clause {
+	if len(s.proofTrace) == 0 {
+		return nil
+	}
+
+	learned := make(map[int]proofStep, len(s.proofTrace))
+	for _, step := range s.proofTrace {
+		learned[step.Learned] = step
+	}
+
+	root := s.proofTrace[len(s.proofTrace)-1].Learned
+
+	seen := map[int]struct{}{}
+	core := []cnf.Clause{}
+
+	var walk func(id int)
+	walk = func(id int) {
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+
+		// Literals false at decision level 0 are never added to a
+		// conflict clause, so their reasons aren't recorded antecedents.
+		// Follow them here so the clauses they rest on are in the core.
+		for _, l := range s.clauseByID[id] {
+			if level, ok := s.m.set[l.Negate()]; ok && level == 0 {
+				if reason, ok := s.reasonMap[l.Negate()]; ok && len(reason) > 0 {
+					walk(s.registerClause(reason))
+				}
+			}
+		}
+
+		step, ok := learned[id]
+		if !ok {
+			core = append(core, s.clauseByID[id])
+			return
+		}
+
+		for _, ant := range step.Antecedents {
+			walk(ant)
+		}
+	}
+	walk(root)
+
+	return core
+}
+
+// Proof returns the full DRAT-style resolution trace recorded while
+// solving. It is only meaningful after Solve returns false.
+func (s *Solver) Proof() ResolutionProof {
+	clauses := make(map[int]cnf.Clause, len(s.clauseByID))
+	for id, c := range s.clauseByID {
+		clauses[id] = c
+	}
+
+	steps := make([]proofStep, len(s.proofTrace))
+	copy(steps, s.proofTrace)
+
+	return ResolutionProof{steps: steps, clauses: clauses}
+}
+
+// WriteDRAT emits the solver's recorded resolution proof in DRAT format.
+// See ResolutionProof.WriteDRAT.
+func (s *Solver) WriteDRAT(w io.Writer) error {
+	return s.Proof().WriteDRAT(w)
+}
@@ -0,0 +1,38 @@
+package sat
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mitchellh/go-sat/cnf"
+)
+
+func TestSolver_unsatCore(t *testing.T) {
+	formula := [][]int{
+		{4},
+		{6},
+		{-4, -6},
+	}
+
+	s := New()
+	s.Trace = true
+	s.Tracer = newTracer(t)
+	s.AddFormula(cnf.NewFormulaFromInts(formula))
+
+	if s.Solve() {
+		t.Fatal("expected unsat")
+	}
+
+	core := s.UnsatCore()
+	if len(core) == 0 {
+		t.Fatal("expected a non-empty unsat core")
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteDRAT(&buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if buf.Len() == 0 {
+		t.Fatal("expected a non-empty DRAT proof")
+	}
+}
@@ -0,0 +1,273 @@
+package sat
+
+// This file contains the VSIDS ("Variable State Independent Decaying
+// Sum") activity-based branching heuristic and the Luby-sequence restart
+// controller. Together these replace the naive "first unassigned
+// variable" decision strategy with the heuristic used by modern CDCL
+// solvers such as MiniSat and Chaff.
+
+// varHeap is a binary max-heap over variable activity. It is stored as a
+// slice of variable IDs with a reverse index (pos) so that increase-key
+// operations triggered by activity bumps are O(log n) rather than
+// requiring a full re-sort.
+type varHeap struct {
+	heap []int
+	pos  map[int]int
+
+	// act is shared with Solver.activity. Activity decay scales every
+	// entry by the same factor, so it never changes the relative order
+	// of the heap and never requires a re-heapify; only bumpActivity
+	// (an increase-key) does.
+	act map[int]float64
+}
+
+func newVarHeap(act map[int]float64) *varHeap {
+	return &varHeap{pos: make(map[int]int), act: act}
+}
+
+func (h *varHeap) len() int {
+	return len(h.heap)
+}
+
+func (h *varHeap) contains(v int) bool {
+	_, ok := h.pos[v]
+	return ok
+}
+
+// push inserts v into the heap. It is a no-op if v is already present.
+func (h *varHeap) push(v int) {
+	if h.contains(v) {
+		return
+	}
+
+	h.heap = append(h.heap, v)
+	idx := len(h.heap) - 1
+	h.pos[v] = idx
+	h.siftUp(idx)
+}
+
+// pop removes and returns the variable with the highest activity.
+func (h *varHeap) pop() (int, bool) {
+	if len(h.heap) == 0 {
+		return 0, false
+	}
+
+	top := h.heap[0]
+	last := len(h.heap) - 1
+	h.swap(0, last)
+	h.heap = h.heap[:last]
+	delete(h.pos, top)
+	if len(h.heap) > 0 {
+		h.siftDown(0)
+	}
+
+	return top, true
+}
+
+// fix restores the heap property for v after its activity has increased.
+// Only upward movement is ever necessary since bumpActivity only grows
+// activity between decays.
+func (h *varHeap) fix(v int) {
+	idx, ok := h.pos[v]
+	if !ok {
+		return
+	}
+
+	h.siftUp(idx)
+}
+
+func (h *varHeap) siftUp(idx int) {
+	for idx > 0 {
+		parent := (idx - 1) / 2
+		if !h.less(idx, parent) {
+			break
+		}
+
+		h.swap(idx, parent)
+		idx = parent
+	}
+}
+
+func (h *varHeap) siftDown(idx int) {
+	n := len(h.heap)
+	for {
+		left, right := 2*idx+1, 2*idx+2
+		top := idx
+		if left < n && h.less(left, top) {
+			top = left
+		}
+		if right < n && h.less(right, top) {
+			top = right
+		}
+		if top == idx {
+			break
+		}
+
+		h.swap(idx, top)
+		idx = top
+	}
+}
+
+// less reports whether the variable at heap index i has strictly higher
+// activity than the variable at index j. The name matches the usual
+// container/heap convention even though this heap orders by descending
+// activity (a max-heap): the highest-activity variable is always the
+// root.
+func (h *varHeap) less(i, j int) bool {
+	return h.act[h.heap[i]] > h.act[h.heap[j]]
+}
+
+func (h *varHeap) swap(i, j int) {
+	h.heap[i], h.heap[j] = h.heap[j], h.heap[i]
+	h.pos[h.heap[i]] = i
+	h.pos[h.heap[j]] = j
+}
+
+//-------------------------------------------------------------------
+// Activity bumping and decay
+//-------------------------------------------------------------------
+
+// bumpActivity increases v's VSIDS activity and re-orders the heap if v
+// is currently tracked in it. Activities are rescaled if they grow large
+// enough to risk losing precision.
+func (s *Solver) bumpActivity(v int) {
+	s.activity[v] += s.varInc
+	if s.activity[v] > 1e100 {
+		s.rescaleActivity()
+	}
+
+	s.varHeap.fix(v)
+}
+
+// rescaleActivity divides every activity (and the shared increment) down
+// by 1e100. This preserves relative ordering while keeping the values in
+// a sane float64 range.
+func (s *Solver) rescaleActivity() {
+	for v := range s.activity {
+		s.activity[v] *= 1e-100
+	}
+	s.varInc *= 1e-100
+}
+
+// decayActivity scales every variable's activity by VarDecay. It is
+// called once per conflict so that variables implicated in recent
+// conflicts outweigh ones from conflicts long past.
+func (s *Solver) decayActivity() {
+	if s.VarDecay <= 0 || s.VarDecay >= 1 {
+		return
+	}
+
+	for v := range s.activity {
+		s.activity[v] *= s.VarDecay
+	}
+}
+
+// bumpLearnedClause bumps the activity of every variable that appears in
+// the just-learned conflict clause. Called at the end of
+// applyExplainUIP, once s.c holds the final learned clause.
+func (s *Solver) bumpLearnedClause() {
+	for _, l := range s.c {
+		v := int(l)
+		if v < 0 {
+			v = -v
+		}
+
+		s.bumpActivity(v)
+	}
+}
+
+// syncVarHeap ensures every known variable is represented in the decision
+// heap. This is called lazily from selectLiteral rather than eagerly from
+// AddClause/AddFormula, since new variables can be introduced at any
+// point before the first decision that needs them.
+func (s *Solver) syncVarHeap() {
+	for raw := range s.vars {
+		if !s.varHeap.contains(raw) {
+			s.varHeap.push(raw)
+		}
+	}
+}
+
+// reinsertUnassigned pushes every currently-unassigned variable back onto
+// the decision heap. This is necessary after a backjump or restart:
+// variables unassigned by the trim are no longer represented in the heap
+// and must be made available for selection again. It also pulls
+// propQhead back to the end of the trimmed trail, so literals asserted
+// after the trim are propagated.
+func (s *Solver) reinsertUnassigned() {
+	if s.propQhead > len(s.m.elems) {
+		s.propQhead = len(s.m.elems)
+	}
+
+	assigned := map[int]struct{}{}
+	for _, e := range s.m.elems {
+		lit := e.Lit
+		if lit < 0 {
+			lit = -lit
+		}
+		assigned[int(lit)] = struct{}{}
+	}
+
+	for raw := range s.vars {
+		if _, ok := assigned[raw]; ok {
+			continue
+		}
+		if !s.varHeap.contains(raw) {
+			s.varHeap.push(raw)
+		}
+	}
+}
+
+//-------------------------------------------------------------------
+// Luby-sequence restarts
+//-------------------------------------------------------------------
+
+// luby returns the i'th (1-indexed) term of the base-2 Luby sequence:
+// 1, 1, 2, 1, 1, 2, 4, 1, 1, 2, 1, 1, 2, 4, 8, ...
+//
+// This is the standard restart schedule from Luby, Sinclair, and
+// Zuckerman (1993): it grows slowly enough that early restarts stay
+// cheap, but eventually grows exponentially so restarts become rare as
+// the search matures.
+func luby(i int) int {
+	k := 1
+	for i > (1<<uint(k))-1 {
+		k++
+	}
+
+	if i == (1<<uint(k))-1 {
+		return 1 << uint(k-1)
+	}
+
+	return luby(i - (1 << uint(k-1)) + 1)
+}
+
+// shouldRestart reports whether the solver has hit the current restart
+// threshold, measured in conflicts since the last restart.
+func (s *Solver) shouldRestart() bool {
+	if !s.RestartEnabled {
+		return false
+	}
+
+	unit := s.RestartUnit
+	if unit <= 0 {
+		unit = 100
+	}
+
+	return s.conflictsSinceRestart >= unit*luby(s.restartCount+1)
+}
+
+// restart trims the trail back to decision level 0, preserving every
+// learned clause, and makes all variables available for selection again.
+func (s *Solver) restart() {
+	if s.Trace {
+		s.Tracer.Printf("[TRACE] sat: restart #%d after %d conflicts",
+			s.restartCount+1, s.conflictsSinceRestart)
+	}
+
+	s.m.TrimToLevel(0)
+	s.reinsertUnassigned()
+
+	s.restartCount++
+	s.conflictsSinceRestart = 0
+}
@@ -0,0 +1,43 @@
+package sat
+
+import "testing"
+
+func TestLuby(t *testing.T) {
+	// The first 15 terms of the base-2 Luby sequence, per Luby, Sinclair,
+	// and Zuckerman (1993).
+	expected := []int{1, 1, 2, 1, 1, 2, 4, 1, 1, 2, 1, 1, 2, 4, 8}
+
+	for i, want := range expected {
+		if got := luby(i + 1); got != want {
+			t.Fatalf("luby(%d) = %d, want %d", i+1, got, want)
+		}
+	}
+}
+
+func TestVarHeap(t *testing.T) {
+	act := map[int]float64{1: 1, 2: 5, 3: 3}
+	h := newVarHeap(act)
+	h.push(1)
+	h.push(2)
+	h.push(3)
+
+	if v, ok := h.pop(); !ok || v != 2 {
+		t.Fatalf("expected var 2 (highest activity), got %d", v)
+	}
+
+	// Bump var 1's activity above var 3's and confirm it now pops first.
+	act[1] = 10
+	h.fix(1)
+
+	if v, ok := h.pop(); !ok || v != 1 {
+		t.Fatalf("expected var 1 after activity bump, got %d", v)
+	}
+
+	if v, ok := h.pop(); !ok || v != 3 {
+		t.Fatalf("expected var 3 last, got %d", v)
+	}
+
+	if _, ok := h.pop(); ok {
+		t.Fatal("expected heap to be empty")
+	}
+}
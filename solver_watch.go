@@ -0,0 +1,232 @@
+package sat
+
+import "github.com/mitchellh/go-sat/cnf"
+
+// This file implements unit propagation (BCP) using a two-watched-literal
+// scheme, replacing the naive approach of rescanning every clause for a
+// unit on every assignment. For every clause of length >= 2 we track two
+// watched literals; a clause only needs attention when one of its
+// watched literals is falsified, which keeps BCP cost roughly
+// proportional to the number of assignments made rather than to
+// clauses x literals. This is the same approach used by Chaff and
+// MiniSat.
+//
+// Clauses of length 1 are handled directly at the top level: they're
+// asserted the moment they're seen and never need a watch.
+
+// watcher records one clause watching a particular literal, plus a
+// cached "blocker": the clause's other watched literal. If the blocker
+// is already true, the clause is satisfied and doesn't need to be
+// inspected further, even if the literal being watched was just
+// falsified.
+type watcher struct {
+	clause  cnf.Clause
+	blocker cnf.Literal
+}
+
+// litTrue reports whether l is currently assigned true.
+func (s *Solver) litTrue(l cnf.Literal) bool {
+	_, ok := s.m.set[l]
+	return ok
+}
+
+// litFalse reports whether l is currently assigned false (i.e. its
+// negation is assigned true).
+func (s *Solver) litFalse(l cnf.Literal) bool {
+	_, ok := s.m.set[l.Negate()]
+	return ok
+}
+
+// addWatch registers c as watching lit, with other serving as the
+// cached blocker.
+func (s *Solver) addWatch(lit cnf.Literal, c cnf.Clause, other cnf.Literal) {
+	s.watches[lit] = append(s.watches[lit], watcher{clause: c, blocker: other})
+}
+
+// watchClause sets up the initial two watched literals for a clause of
+// length >= 2. It cannot simply watch c[0]/c[1]: a freshly learned
+// clause is built by applyExplainUIP as [cP-literals..., the asserting
+// UIP literal], so when len(cP) >= 2 (the common case) both c[0] and
+// c[1] are already-false cP literals and the actual unit literal sits
+// unwatched further along. Since registerNewClauses only runs on the
+// *next* unitPropagate call — after applyBackjump has already trimmed
+// the trail — the falsification event that would otherwise trigger a
+// watch re-scan has already happened and will never recur, leaving the
+// clause permanently dead to the watch scheme.
+func (s *Solver) watchClause(c cnf.Clause) {
+	i, j := s.pickInitialWatches(c)
+	s.addWatch(c[i], c, c[j])
+	s.addWatch(c[j], c, c[i])
+}
+
+// pickInitialWatches selects two indices into c to use as its initial
+// watched literals, preferring literals that aren't currently false.
+// If only one non-false literal exists (the asserting/UIP literal of a
+// freshly learned clause), it's paired with the false literal sitting
+// at the highest decision level, as MiniSat does when attaching a
+// learned clause — that's the assignment which will be undone first on
+// backtracking, so the watch reactivates at the earliest possible
+// moment.
+func (s *Solver) pickInitialWatches(c cnf.Clause) (int, int) {
+	first := -1
+	for i, lit := range c {
+		if !s.litFalse(lit) {
+			if first == -1 {
+				first = i
+				continue
+			}
+
+			return first, i
+		}
+	}
+
+	if first == -1 {
+		// Every literal is false. This clause is itself a conflict;
+		// there's no better choice than the first two.
+		return 0, 1
+	}
+
+	second, bestLevel := -1, -1
+	for i, lit := range c {
+		if i == first {
+			continue
+		}
+
+		if level := s.m.Level(lit.Negate()); level > bestLevel {
+			bestLevel = level
+			second = i
+		}
+	}
+
+	return first, second
+}
+
+// registerNewClauses indexes every clause appended to s.f since the
+// last call: unit clauses are asserted immediately, and every other
+// clause gets its initial pair of watched literals. This runs lazily so
+// that clauses learned mid-search (appended to s.f by Solve) are picked
+// up automatically.
+func (s *Solver) registerNewClauses() {
+	for ; s.watchedCount < len(s.f); s.watchedCount++ {
+		c := s.f[s.watchedCount]
+
+		switch {
+		case len(c) == 0:
+			// The empty clause is a standing conflict; IsFormulaFalse
+			// will surface it, nothing to watch.
+		case len(c) == 1:
+			if !s.litTrue(c[0]) && !s.litFalse(c[0]) {
+				if s.Trace {
+					s.Tracer.Printf("[TRACE] sat: asserting unit clause %v", c)
+				}
+				s.assertLiteral(c[0], false)
+				s.reasonMap[c[0]] = c
+			}
+		default:
+			s.watchClause(c)
+		}
+	}
+}
+
+// findNewWatch looks for a literal in c, other than avoid and blocker
+// (the clause's two current watched literals), that isn't currently
+// false. Such a literal can take over the watch that avoid is giving up.
+func (s *Solver) findNewWatch(c cnf.Clause, avoid, blocker cnf.Literal) (cnf.Literal, bool) {
+	for _, lit := range c {
+		if lit == avoid || lit == blocker {
+			continue
+		}
+		if s.litFalse(lit) {
+			continue
+		}
+
+		return lit, true
+	}
+
+	return 0, false
+}
+
+// propagateWatches handles the consequences of asserting l: every
+// clause watching l.Negate() (which just became false) is revisited,
+// either finding it a new watch, discovering it's now unit (and
+// asserting the implied literal), or leaving it as-is because its
+// blocker already satisfies it. It returns false the moment a clause is
+// found with both watches false, since that's a conflict the caller
+// will pick up via IsFormulaFalse.
+func (s *Solver) propagateWatches(l cnf.Literal) bool {
+	key := l.Negate()
+	ws := s.watches[key]
+	if len(ws) == 0 {
+		return true
+	}
+
+	kept := ws[:0]
+	for i := 0; i < len(ws); i++ {
+		w := ws[i]
+
+		if s.litTrue(w.blocker) {
+			kept = append(kept, w)
+			continue
+		}
+
+		if other, ok := s.findNewWatch(w.clause, key, w.blocker); ok {
+			s.addWatch(other, w.clause, w.blocker)
+
+			// The clause's other watcher still names key as its partner;
+			// point its blocker at the new watch.
+			for k, ow := range s.watches[w.blocker] {
+				if &ow.clause[0] == &w.clause[0] {
+					s.watches[w.blocker][k].blocker = other
+					break
+				}
+			}
+			continue
+		}
+
+		// No replacement watch available: the clause's fate now rests
+		// entirely on its blocker.
+		kept = append(kept, w)
+
+		if s.litFalse(w.blocker) {
+			// Conflict: both watched literals are false. Preserve the
+			// remaining, not-yet-inspected watchers and bail; the
+			// caller's IsFormulaFalse check will find and report the
+			// conflicting clause.
+			kept = append(kept, ws[i+1:]...)
+			s.watches[key] = kept
+			return false
+		}
+
+		if s.Trace {
+			s.Tracer.Printf(
+				"[TRACE] sat: clause %v is unit on %d", w.clause, w.blocker)
+		}
+		s.assertLiteral(w.blocker, false)
+		s.reasonMap[w.blocker] = w.clause
+	}
+
+	s.watches[key] = kept
+	return true
+}
+
+// unitPropagate performs unit propagation to a fixed point: it indexes
+// any clauses added since the last call, then drains the propagation
+// queue (every literal asserted since propQhead) through the watch
+// lists, asserting every literal forced as a result, until either
+// nothing more is implied or a conflict is found.
+func (s *Solver) unitPropagate() {
+	s.registerNewClauses()
+
+	if s.propQhead > len(s.m.elems) {
+		s.propQhead = len(s.m.elems)
+	}
+
+	for s.propQhead < len(s.m.elems) {
+		l := s.m.elems[s.propQhead].Lit
+		s.propQhead++
+
+		if !s.propagateWatches(l) {
+			return
+		}
+	}
+}